@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
+
+	"golang.org/x/term"
 )
 
 // ProgressBar is a thread-safe, simple
@@ -16,27 +20,110 @@ type ProgressBar struct {
 	state  state
 	config config
 
+	// pool, when set, owns rendering: Add marks the bar dirty instead of
+	// writing to config.writer directly.
+	pool *Pool
+
 	lock sync.RWMutex
 }
 
 type state struct {
-	currentNum        int
+	currentNum        int64
 	currentPercent    int
 	lastPercent       int
 	currentSaucerSize int
 
+	currentFrame int
+	description  string
+
 	lastShown time.Time
 	startTime time.Time
 }
 
 type config struct {
-	max                  int // max number of the counter
+	max                  int64 // max number of the counter
 	width                int
 	writer               io.Writer
 	theme                Theme
 	renderWithBlankState bool
+
+	// useBytes formats the counters as human-readable byte sizes
+	// (KiB/MiB/GiB, or KB/MB/GB when useSIPrefix is set).
+	useBytes    bool
+	useSIPrefix bool
+
+	// fullWidth sizes the bar at render time to fill the terminal width,
+	// falling back to the fixed width when the writer isn't a TTY.
+	fullWidth bool
+
+	// useANSICodes erases to end-of-line with \033[K instead of padding
+	// the rendered line with trailing spaces.
+	useANSICodes bool
+
+	// spinnerFrames, when non-empty, puts the bar into indeterminate
+	// mode: instead of a percentage bar it cycles through these frames.
+	spinnerFrames []string
+
+	// throttleDuration, when non-zero, limits renders triggered by Add to
+	// at most one per duration; the final render is always forced.
+	throttleDuration time.Duration
+
+	tmplSource string
+	tmpl       *template.Template
+}
+
+// State is the data a bar template is rendered against.
+type State struct {
+	Percent     int
+	Current     int64
+	Max         int64
+	CurrentStr  string // Current, formatted as bytes when OptionShowBytes is set
+	MaxStr      string // Max, formatted as bytes when OptionShowBytes is set
+	Elapsed     time.Duration
+	ETA         time.Duration
+	Rate        float64
+	Bar         string
+	Spinner     string
+	Description string
 }
 
+// Preset names a built-in template, for use with OptionPreset.
+type Preset string
+
+// Built-in presets, modeled after the composition style of pb/v3.
+const (
+	PresetDefault Preset = "default"
+	PresetSimple  Preset = "simple"
+	PresetFull    Preset = "full"
+)
+
+const defaultBarTemplate = `{{counters .}} {{bar .}} [{{rtime .}}]            `
+const defaultSpinnerTemplate = `{{.Spinner}} {{.Description}} [{{rtime .}}]`
+
+var presetTemplates = map[Preset]string{
+	PresetDefault: defaultBarTemplate,
+	PresetSimple:  `{{percent .}} {{bar .}}`,
+	PresetFull:    `{{counters .}} {{bar .}} [{{rtime .}} {{speed .}}] {{.Description}}`,
+}
+
+var templateFuncs = template.FuncMap{
+	"bar": func(s State) string { return s.Bar },
+	"counters": func(s State) string {
+		return fmt.Sprintf("%4d%% (%s/%s)", s.Percent, s.CurrentStr, s.MaxStr)
+	},
+	"percent": func(s State) string { return fmt.Sprintf("%4d%%", s.Percent) },
+	"speed":   func(s State) string { return fmt.Sprintf("%.2f/s", s.Rate) },
+	"rtime": func(s State) string {
+		return fmt.Sprintf("%s:%s", s.Elapsed.String(), s.ETA.String())
+	},
+}
+
+// SpinnerFramesASCII is the default indeterminate-mode frame set.
+var SpinnerFramesASCII = []string{"|", "/", "-", "\\"}
+
+// SpinnerFramesBraille is a Unicode braille indeterminate-mode frame set.
+var SpinnerFramesBraille = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
 // Theme defines the elements of the bar
 type Theme struct {
 	Saucer        string
@@ -76,10 +163,108 @@ func OptionSetRenderBlankState(r bool) Option {
 	}
 }
 
+// OptionSpinner puts the bar into indeterminate mode, cycling through the
+// given frames on each Add instead of rendering a percentage bar. Pass nil
+// (or construct with max <= 0) to fall back to SpinnerFramesASCII.
+func OptionSpinner(frames []string) Option {
+	return func(p *ProgressBar) {
+		p.config.spinnerFrames = frames
+	}
+}
+
+// OptionThrottle limits how often Add is allowed to redraw the bar, which
+// keeps fast loops (e.g. hashing millions of small items) from bottlenecking
+// on terminal writes. The final render, when currentNum reaches max, is
+// always forced through regardless of the throttle.
+func OptionThrottle(d time.Duration) Option {
+	return func(p *ProgressBar) {
+		p.config.throttleDuration = d
+	}
+}
+
+// OptionTemplate sets a custom text/template for rendering the bar. The
+// template is executed against a State and has the helper funcs bar,
+// counters, speed, rtime and percent available.
+func OptionTemplate(s string) Option {
+	return func(p *ProgressBar) {
+		p.config.tmplSource = s
+	}
+}
+
+// OptionPreset sets the bar's template to one of the named presets.
+func OptionPreset(preset Preset) Option {
+	return func(p *ProgressBar) {
+		p.config.tmplSource = presetTemplates[preset]
+	}
+}
+
+// OptionShowBytes formats the current/max counters as human-readable byte
+// sizes (KiB/MiB/GiB) instead of raw numbers.
+func OptionShowBytes(b bool) Option {
+	return func(p *ProgressBar) {
+		p.config.useBytes = b
+	}
+}
+
+// OptionUseSIPrefix switches byte formatting (see OptionShowBytes) to SI
+// decimal prefixes (KB/MB/GB, base 1000) instead of binary ones (base 1024).
+func OptionUseSIPrefix(b bool) Option {
+	return func(p *ProgressBar) {
+		p.config.useSIPrefix = b
+	}
+}
+
+// OptionFullWidth sizes the bar, at render time, to fill the entire
+// terminal line (prefix, bar and suffix included), re-measuring on each
+// render to handle terminal resizes. When the writer is not a TTY, it
+// falls back to the fixed width set by OptionSetWidth.
+func OptionFullWidth() Option {
+	return func(p *ProgressBar) {
+		p.config.fullWidth = true
+	}
+}
+
+// OptionUseANSICodes renders with \033[K (erase-to-end-of-line) instead of
+// padding the line out to its previous length with trailing spaces.
+func OptionUseANSICodes(b bool) Option {
+	return func(p *ProgressBar) {
+		p.config.useANSICodes = b
+	}
+}
+
+// humanizeBytes formats n as a human-readable byte size.
+func humanizeBytes(n int64, si bool) string {
+	base := 1024.0
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	if si {
+		base = 1000.0
+		units = []string{"B", "KB", "MB", "GB", "TB", "PB"}
+	}
+
+	f := float64(n)
+	if f < base {
+		return fmt.Sprintf("%.0f %s", f, units[0])
+	}
+
+	exp := 0
+	for f >= base && exp < len(units)-1 {
+		f /= base
+		exp++
+	}
+
+	return fmt.Sprintf("%.2f %s", f, units[exp])
+}
+
 var defaultTheme = Theme{Saucer: "█", SaucerPadding: " ", BarStart: "|", BarEnd: "|"}
 
 // NewOptions constructs a new instance of ProgressBar, with any options you specify
 func NewOptions(max int, options ...Option) *ProgressBar {
+	return NewOptions64(int64(max), options...)
+}
+
+// NewOptions64 is like NewOptions but takes an int64 max, for counters that
+// don't fit in an int (e.g. byte counts).
+func NewOptions64(max int64, options ...Option) *ProgressBar {
 	b := ProgressBar{
 		state: getBlankState(),
 		config: config{
@@ -95,6 +280,19 @@ func NewOptions(max int, options ...Option) *ProgressBar {
 		o(&b)
 	}
 
+	if b.config.max <= 0 && len(b.config.spinnerFrames) == 0 {
+		b.config.spinnerFrames = SpinnerFramesASCII
+	}
+
+	if b.config.tmplSource == "" {
+		if b.config.max <= 0 {
+			b.config.tmplSource = defaultSpinnerTemplate
+		} else {
+			b.config.tmplSource = defaultBarTemplate
+		}
+	}
+	b.config.tmpl = template.Must(template.New("progressbar").Funcs(templateFuncs).Parse(b.config.tmplSource))
+
 	if b.config.renderWithBlankState {
 		b.RenderBlank()
 	}
@@ -116,6 +314,27 @@ func New(max int) *ProgressBar {
 	return NewOptions(max)
 }
 
+// New64 is like New but takes an int64 max, for counters that don't fit in
+// an int (e.g. byte counts).
+func New64(max int64) *ProgressBar {
+	return NewOptions64(max)
+}
+
+// DefaultBytes returns a ProgressBar preconfigured for tracking a byte
+// count: it writes to stderr and formats its counters as human-readable
+// byte sizes. description is shown alongside the bar.
+func DefaultBytes(max int64, description string) *ProgressBar {
+	b := NewOptions64(
+		max,
+		OptionSetWriter(os.Stderr),
+		OptionShowBytes(true),
+		OptionSetWidth(40),
+	)
+	b.SetDescription(description)
+
+	return b
+}
+
 // RenderBlank renders the current bar state, you can use this to render a 0% state
 func (p *ProgressBar) RenderBlank() error {
 	return renderProgressBar(p.config, p.state)
@@ -130,14 +349,50 @@ func (p *ProgressBar) Reset() {
 	p.state = getBlankState()
 }
 
+// SetDescription sets a message that is rendered alongside the bar, e.g. in
+// indeterminate mode it is printed next to the spinner frame.
+func (p *ProgressBar) SetDescription(description string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.state.description = description
+}
+
 // Add with increase the current count on the progress bar
 func (p *ProgressBar) Add(num int) error {
+	return p.Add64(int64(num))
+}
+
+// Add64 is like Add but takes an int64, for counters that don't fit in an
+// int (e.g. byte counts).
+func (p *ProgressBar) Add64(num int64) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	if p.config.max == 0 {
-		return errors.New("max must be greater than 0")
+	return p.addUnlocked(num)
+}
+
+// Set sets the current count on the progress bar to n.
+func (p *ProgressBar) Set(n int) error {
+	return p.Set64(int64(n))
+}
+
+// Set64 is like Set but takes an int64, for counters that don't fit in an
+// int (e.g. byte counts).
+func (p *ProgressBar) Set64(n int64) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.addUnlocked(n - p.state.currentNum)
+}
+
+func (p *ProgressBar) addUnlocked(num int64) error {
+	if p.config.max <= 0 {
+		p.state.currentNum += num
+		p.state.currentFrame = (p.state.currentFrame + 1) % len(p.config.spinnerFrames)
+		return p.render(false)
 	}
+
 	p.state.currentNum += num
 	percent := float64(p.state.currentNum) / float64(p.config.max)
 	p.state.currentSaucerSize = int(percent * float64(p.config.width))
@@ -150,28 +405,158 @@ func (p *ProgressBar) Add(num int) error {
 	}
 
 	if updateBar {
-		return renderProgressBar(p.config, p.state)
+		return p.render(p.state.currentNum == p.config.max)
 	}
 
 	return nil
 }
 
-func renderProgressBar(c config, s state) error {
-	var leftTime float64
+// Reader wraps r so that every Read advances the bar by the number of bytes
+// read, e.g. for tracking an http.Response.Body download.
+func (p *ProgressBar) Reader(r io.Reader) io.Reader {
+	return &progressBarReader{reader: r, bar: p}
+}
+
+type progressBarReader struct {
+	reader io.Reader
+	bar    *ProgressBar
+}
+
+func (r *progressBarReader) Read(b []byte) (int, error) {
+	n, err := r.reader.Read(b)
+	if n > 0 {
+		// Ignore the bar's own bookkeeping error here: max is usually an
+		// estimate (e.g. a Content-Length header), and a real stream
+		// regularly reads more or less than that estimate (chunked
+		// transfer, transparent gzip, redirects). The read itself
+		// succeeded and must not be reported as failed, or lost data,
+		// just because the progress estimate was off.
+		_ = r.bar.Add64(int64(n))
+	}
+
+	return n, err
+}
+
+// Writer wraps w so that every Write advances the bar by the number of
+// bytes written, e.g. for tracking a file copy.
+func (p *ProgressBar) Writer(w io.Writer) io.Writer {
+	return &progressBarWriter{writer: w, bar: p}
+}
+
+type progressBarWriter struct {
+	writer io.Writer
+	bar    *ProgressBar
+}
+
+func (w *progressBarWriter) Write(b []byte) (int, error) {
+	n, err := w.writer.Write(b)
+	if n > 0 {
+		// See progressBarReader.Read: the bar's own bookkeeping error is
+		// not the wrapped writer's problem, and must not be reported as
+		// a failed write.
+		_ = w.bar.Add64(int64(n))
+	}
+
+	return n, err
+}
+
+// render draws the bar, respecting OptionThrottle unless force is true (used
+// to guarantee the final frame is always shown). If the bar belongs to a
+// Pool, it marks itself dirty instead: the pool owns writing to the
+// terminal and redraws all of its bars together on its own schedule.
+func (p *ProgressBar) render(force bool) error {
+	if p.pool != nil {
+		p.pool.markDirty(p)
+		return nil
+	}
+
+	now := time.Now()
+	if !force && p.config.throttleDuration > 0 && now.Sub(p.state.lastShown) < p.config.throttleDuration {
+		return nil
+	}
+	p.state.lastShown = now
+
+	return renderProgressBar(p.config, p.state)
+}
+
+// attachPool associates the bar with a Pool; see Pool.Add.
+func (p *ProgressBar) attachPool(pool *Pool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.pool = pool
+}
+
+// Finish forces a final render of the bar, flushing the last frame even if
+// OptionThrottle would otherwise have suppressed it, and writes a trailing
+// newline so later output doesn't overwrite the bar's line.
+func (p *ProgressBar) Finish() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if err := p.render(true); err != nil {
+		return err
+	}
+
+	// A bar owned by a Pool doesn't write to config.writer itself; the
+	// pool manages the region's newlines for all of its bars together.
+	if p.pool != nil {
+		return nil
+	}
+
+	_, err := io.WriteString(p.config.writer, "\n")
+	return err
+}
+
+func buildState(c config, s state) State {
+	elapsed := time.Duration(time.Since(s.startTime).Seconds()) * time.Second
+
+	var eta time.Duration
+	var rate float64
 	if s.currentNum > 0 {
-		leftTime = time.Since(s.startTime).Seconds() / float64(s.currentNum) * (float64(c.max) - float64(s.currentNum))
+		secs := time.Since(s.startTime).Seconds()
+		rate = float64(s.currentNum) / secs
+		if c.max > 0 {
+			eta = time.Duration(secs/float64(s.currentNum)*(float64(c.max)-float64(s.currentNum))) * time.Second
+		}
 	}
 
-	str := fmt.Sprintf("\r%4d%% %s%s%s%s [%s:%s]            ",
-		s.currentPercent,
-		c.theme.BarStart,
-		strings.Repeat(c.theme.Saucer, s.currentSaucerSize),
-		strings.Repeat(c.theme.SaucerPadding, c.width-s.currentSaucerSize),
-		c.theme.BarEnd,
-		(time.Duration(time.Since(s.startTime).Seconds()) * time.Second).String(),
-		(time.Duration(leftTime) * time.Second).String(),
-	)
-	_, err := io.WriteString(c.writer, str)
+	var spinner string
+	if len(c.spinnerFrames) > 0 {
+		spinner = c.spinnerFrames[s.currentFrame]
+	}
+
+	currentStr, maxStr := strconv.FormatInt(s.currentNum, 10), strconv.FormatInt(c.max, 10)
+	if c.useBytes {
+		currentStr, maxStr = humanizeBytes(s.currentNum, c.useSIPrefix), humanizeBytes(c.max, c.useSIPrefix)
+	}
+
+	return State{
+		Percent:    s.currentPercent,
+		Current:    s.currentNum,
+		Max:        c.max,
+		CurrentStr: currentStr,
+		MaxStr:     maxStr,
+		Elapsed:    elapsed,
+		ETA:        eta,
+		Rate:       rate,
+		Bar: fmt.Sprintf("%s%s%s%s",
+			c.theme.BarStart,
+			strings.Repeat(c.theme.Saucer, s.currentSaucerSize),
+			strings.Repeat(c.theme.SaucerPadding, c.width-s.currentSaucerSize),
+			c.theme.BarEnd),
+		Spinner:     spinner,
+		Description: s.description,
+	}
+}
+
+func renderProgressBar(c config, s state) error {
+	str, err := renderLine(c, s)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(c.writer, "\r"+str)
 	if err != nil {
 		return err
 	}
@@ -182,3 +567,224 @@ func renderProgressBar(c config, s state) error {
 
 	return nil
 }
+
+// renderLine resolves OptionFullWidth against the bar's own writer and
+// executes its template, returning the line to print (minus the leading
+// \r, which callers add along with however they terminate it). Both a
+// standalone bar and a bar rendered by a Pool go through this, so
+// OptionFullWidth/OptionUseANSICodes behave the same in either case.
+func renderLine(c config, s state) (string, error) {
+	if c.fullWidth {
+		c.width = fullWidth(c, s)
+	}
+
+	// currentSaucerSize was sized against config.width at the last Add; on
+	// a narrow terminal (or once a bar with OptionFullWidth resizes down),
+	// the resolved width above can be smaller than that stale value. Clamp
+	// it here so buildState never computes a negative padding repeat.
+	if s.currentSaucerSize > c.width {
+		s.currentSaucerSize = c.width
+	}
+
+	str, err := executeTemplate(c, s)
+	if err != nil {
+		return "", err
+	}
+
+	if c.useANSICodes {
+		str = strings.TrimRight(str, " ") + "\033[K"
+	}
+
+	return str, nil
+}
+
+func executeTemplate(c config, s state) (string, error) {
+	var buf strings.Builder
+	if err := c.tmpl.Execute(&buf, buildState(c, s)); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// fullWidth measures the terminal and returns the bar width that makes the
+// whole rendered line fill it exactly. It renders once with width 0 to
+// learn how much space the rest of the template (prefix, suffix, spinner,
+// description) takes up, since every extra unit of width adds exactly one
+// rune of bar. The probe also zeroes currentSaucerSize, since it reflects
+// the bar's real (non-zero) width and would otherwise make the padding
+// repeat count go negative. It falls back to c.width when the writer isn't
+// a TTY.
+func fullWidth(c config, s state) int {
+	f, ok := c.writer.(*os.File)
+	if !ok {
+		return c.width
+	}
+
+	termWidth, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return c.width
+	}
+
+	probe := c
+	probe.width = 0
+	probeState := s
+	probeState.currentSaucerSize = 0
+	line, err := executeTemplate(probe, probeState)
+	if err != nil {
+		return c.width
+	}
+
+	width := termWidth - len([]rune(line))
+	if width < 0 {
+		width = 0
+	}
+
+	return width
+}
+
+// defaultPoolRefreshRate is how often a Pool redraws its bars.
+const defaultPoolRefreshRate = 100 * time.Millisecond
+
+// Pool coordinates rendering a set of ProgressBars together in a single
+// terminal region, so concurrent bars don't clobber each other's lines.
+type Pool struct {
+	mu          sync.Mutex
+	writer      io.Writer
+	refreshRate time.Duration
+
+	bars  []*ProgressBar
+	dirty map[*ProgressBar]bool
+
+	linesDrawn int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPool constructs a Pool that renders the given bars together. Bars
+// added later with Pool.Add join the same rendered region.
+func NewPool(bars ...*ProgressBar) *Pool {
+	pool := &Pool{
+		writer:      os.Stdout,
+		refreshRate: defaultPoolRefreshRate,
+		dirty:       make(map[*ProgressBar]bool),
+	}
+
+	for _, b := range bars {
+		pool.Add(b)
+	}
+
+	return pool
+}
+
+// SetRefreshRate changes how often the pool redraws its bars. It must be
+// called before Start.
+func (pool *Pool) SetRefreshRate(d time.Duration) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.refreshRate = d
+}
+
+// SetWriter sets the writer the pool renders its bars to (defaults to
+// os.Stdout, mirroring OptionSetWriter). It must be called before Start.
+func (pool *Pool) SetWriter(w io.Writer) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.writer = w
+}
+
+// Add adds bar to the pool, so its Add calls mark it dirty instead of
+// writing to the terminal directly.
+func (pool *Pool) Add(bar *ProgressBar) {
+	pool.mu.Lock()
+	pool.bars = append(pool.bars, bar)
+	pool.mu.Unlock()
+
+	bar.attachPool(pool)
+}
+
+// markDirty flags bar for redraw on the pool's next tick.
+func (pool *Pool) markDirty(bar *ProgressBar) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.dirty[bar] = true
+}
+
+// Start begins a goroutine that redraws dirty bars at the pool's refresh
+// rate. Call Stop to flush the final frame and end it.
+func (pool *Pool) Start() {
+	pool.stop = make(chan struct{})
+	pool.done = make(chan struct{})
+
+	go func() {
+		defer close(pool.done)
+
+		ticker := time.NewTicker(pool.refreshRate)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				pool.render()
+			case <-pool.stop:
+				pool.render()
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the pool's render goroutine after a final flush.
+func (pool *Pool) Stop() {
+	if pool.stop == nil {
+		return
+	}
+
+	close(pool.stop)
+	<-pool.done
+}
+
+// render redraws every bar that has been marked dirty since the last tick,
+// moving the cursor back up over the region it drew last time.
+//
+// It snapshots pool state under pool.mu and releases it before touching any
+// bar.lock: a bar's Add64/Set64 holds bar.lock while it calls markDirty
+// (which takes pool.mu), so taking bar.lock while pool.mu is still held here
+// would be the reverse order and can deadlock against a concurrent Add.
+func (pool *Pool) render() {
+	pool.mu.Lock()
+	if len(pool.dirty) == 0 {
+		pool.mu.Unlock()
+		return
+	}
+	bars := append([]*ProgressBar(nil), pool.bars...)
+	linesDrawn := pool.linesDrawn
+	pool.dirty = make(map[*ProgressBar]bool)
+	pool.mu.Unlock()
+
+	var buf strings.Builder
+	if linesDrawn > 0 {
+		fmt.Fprintf(&buf, "\033[%dA", linesDrawn)
+	}
+
+	for _, bar := range bars {
+		bar.lock.RLock()
+		line, err := renderLine(bar.config, bar.state)
+		bar.lock.RUnlock()
+		if err != nil {
+			continue
+		}
+
+		fmt.Fprintf(&buf, "\r%s\033[K\n", line)
+	}
+
+	io.WriteString(pool.writer, buf.String())
+
+	pool.mu.Lock()
+	pool.linesDrawn = len(bars)
+	pool.mu.Unlock()
+}